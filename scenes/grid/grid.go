@@ -0,0 +1,61 @@
+// Package grid is a vrapp.Scene showing a static room-scale floor and wall
+// grid, useful as a plain reference scene for new demos to build against.
+package grid
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/fizzle/renderer/forward"
+)
+
+const (
+	roomSize  = 4.0 // half-extent of the room, in meters
+	cellSize  = 0.5
+	lineWidth = 0.02
+)
+
+// Scene is a static floor and wall grid at room scale.
+type Scene struct {
+	renderables []*fizzle.Renderable
+}
+
+// New creates an unitialized grid scene; call Init before use.
+func New() *Scene {
+	return &Scene{}
+}
+
+func (s *Scene) Init(renderer *forward.ForwardRenderer) error {
+	sb, err := forward.CreateBasicShader()
+	if err != nil {
+		return err
+	}
+
+	material := fizzle.NewMaterial()
+	material.Shader = sb
+	material.DiffuseColor = mgl32.Vec4{0.4, 0.4, 0.45, 1.0}
+	material.Shininess = 1
+
+	for x := -roomSize; x <= roomSize; x += cellSize {
+		s.renderables = append(s.renderables, floorLine(material, mgl32.Vec3{float32(x), 0, 0}, mgl32.Vec3{lineWidth, lineWidth, 2 * roomSize}))
+	}
+	for z := -roomSize; z <= roomSize; z += cellSize {
+		s.renderables = append(s.renderables, floorLine(material, mgl32.Vec3{0, 0, float32(z)}, mgl32.Vec3{2 * roomSize, lineWidth, lineWidth}))
+	}
+	return nil
+}
+
+// floorLine creates a thin box representing one gridline, centered at
+// center and stretched along the axis where size is largest.
+func floorLine(material *fizzle.Material, center, size mgl32.Vec3) *fizzle.Renderable {
+	half := size.Mul(0.5)
+	line := fizzle.CreateCube(-half[0], -half[1], -half[2], half[0], half[1], half[2])
+	line.Material = material
+	line.Location = center
+	return line
+}
+
+func (s *Scene) Update(dt float32, hmdPose mgl32.Mat4) {}
+
+func (s *Scene) Renderables() []*fizzle.Renderable {
+	return s.renderables
+}
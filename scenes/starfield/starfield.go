@@ -0,0 +1,125 @@
+// Package starfield is a vrapp.Scene of floating stars the user can grab
+// and hurl with the VR controllers.
+package starfield
+
+import (
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/fizzle/renderer/forward"
+
+	"github.com/discordianfish/vrfoobar/vrinput"
+)
+
+const (
+	starCount      = 1000
+	starGrabRadius = 1.0
+	starThrowSpeed = 8.0
+	fieldExtentX   = 10.0
+	fieldExtentY   = 10.0
+	fieldExtentZ   = 10.0
+)
+
+// Scene is a field of stars the user can grab and hurl with the VR
+// controllers.
+type Scene struct {
+	renderables    []*fizzle.Renderable
+	starVelocities []mgl32.Vec3
+
+	ctrlModel *vrinput.Model
+	heldStar  map[vrinput.Hand]int
+}
+
+// New creates an unitialized starfield scene; call Init before use.
+func New() *Scene {
+	return &Scene{
+		heldStar: map[vrinput.Hand]int{vrinput.HandLeft: -1, vrinput.HandRight: -1},
+	}
+}
+
+func (s *Scene) Init(renderer *forward.ForwardRenderer) error {
+	sb, err := forward.CreateBasicShader()
+	if err != nil {
+		return err
+	}
+
+	redMaterial := fizzle.NewMaterial()
+	redMaterial.Shader = sb
+	redMaterial.DiffuseColor = mgl32.Vec4{1.0, 1.0, 1.0, 1.0}
+	redMaterial.Shininess = 10
+
+	s.renderables = make([]*fizzle.Renderable, starCount)
+	s.starVelocities = make([]mgl32.Vec3, starCount)
+	for i := 0; i < starCount; i++ {
+		x := (rand.Float64() * fieldExtentX) - fieldExtentX/2
+		y := (rand.Float64() * fieldExtentY) - fieldExtentY/2
+		z := (rand.Float64() * fieldExtentZ) - fieldExtentZ/2
+		s.renderables[i] = fizzle.CreateSphere(0.1, 5, 5)
+		s.renderables[i].Material = redMaterial
+		s.renderables[i].Location = mgl32.Vec3{float32(x), float32(y), float32(z)}
+	}
+	return nil
+}
+
+// BindControllers wires the grab-and-hurl demo: pulling a trigger grabs the
+// nearest star to that hand, releasing it hurls the star off in the
+// direction the controller is pointing.
+func (s *Scene) BindControllers(ctrl *vrinput.Model) {
+	s.ctrlModel = ctrl
+	for _, hand := range []vrinput.Hand{vrinput.HandLeft, vrinput.HandRight} {
+		hand := hand
+		ctrl.OnTriggerPress(hand, func(pose mgl32.Mat4) { s.grabStar(hand, pose) })
+		ctrl.OnTriggerRelease(hand, func(pose mgl32.Mat4) { s.hurlStar(hand, pose) })
+	}
+}
+
+// grabStar attaches the nearest free star within starGrabRadius to hand.
+func (s *Scene) grabStar(hand vrinput.Hand, pose mgl32.Mat4) {
+	if s.heldStar[hand] >= 0 {
+		return
+	}
+	handPos := pose.Col(3).Vec3()
+	nearest, nearestDist := -1, float32(starGrabRadius)
+	for i, r := range s.renderables {
+		if s.heldStar[vrinput.HandLeft] == i || s.heldStar[vrinput.HandRight] == i {
+			continue
+		}
+		if d := r.Location.Sub(handPos).Len(); d < nearestDist {
+			nearest, nearestDist = i, d
+		}
+	}
+	s.heldStar[hand] = nearest
+}
+
+// hurlStar releases the star held by hand, if any, and sends it flying in
+// the direction the controller was pointing.
+func (s *Scene) hurlStar(hand vrinput.Hand, pose mgl32.Mat4) {
+	i := s.heldStar[hand]
+	if i < 0 {
+		return
+	}
+	s.heldStar[hand] = -1
+	forward := pose.Mul4x1(mgl32.Vec4{0, 0, -1, 0}).Vec3()
+	s.starVelocities[i] = forward.Normalize().Mul(starThrowSpeed)
+}
+
+func (s *Scene) Update(dt float32, hmdPose mgl32.Mat4) {
+	for hand, i := range s.heldStar {
+		if i < 0 || s.ctrlModel == nil {
+			continue
+		}
+		s.renderables[i].Location = s.ctrlModel.Pose(hand).Col(3).Vec3()
+		s.starVelocities[i] = mgl32.Vec3{}
+	}
+	for i, v := range s.starVelocities {
+		if v.Len() == 0 {
+			continue
+		}
+		s.renderables[i].Location = s.renderables[i].Location.Add(v.Mul(dt))
+	}
+}
+
+func (s *Scene) Renderables() []*fizzle.Renderable {
+	return s.renderables
+}
@@ -0,0 +1,338 @@
+// Package vrapp is the reusable OpenVR harness factored out of the
+// starfield demo: window, vrSystem, eye framebuffers, distortion lens and
+// compositor submit loop. It drives a Scene each frame so new demos can be
+// added without duplicating that boilerplate.
+package vrapp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	"github.com/tbogdala/fizzle/graphicsprovider/opengl"
+	"github.com/tbogdala/fizzle/input/glfwinput"
+	"github.com/tbogdala/fizzle/renderer/forward"
+	"github.com/tbogdala/openvr-go"
+	"github.com/tbogdala/openvr-go/util/fizzlevr"
+
+	"github.com/discordianfish/vrfoobar/vrinput"
+)
+
+// Scene is implemented by a VR demo: it builds its own shaders, materials
+// and renderables in Init, advances them in Update, and hands them back to
+// the harness to draw each frame via Renderables.
+type Scene interface {
+	Init(renderer *forward.ForwardRenderer) error
+	Update(dt float32, hmdPose mgl32.Mat4)
+	Renderables() []*fizzle.Renderable
+}
+
+// ControllerScene is implemented by scenes that want to bind to VR
+// controller input. App calls BindControllers once, after Init, if the
+// active scene implements it.
+type ControllerScene interface {
+	Scene
+	BindControllers(ctrl *vrinput.Model)
+}
+
+// ChaperoneScene is implemented by scenes that want to react to play-area
+// boundary events. App calls BindChaperone once, after Init, if the active
+// scene implements it and a play area is configured.
+type ChaperoneScene interface {
+	Scene
+	BindChaperone(chaperone *Chaperone)
+}
+
+// App is the reusable VR harness driving a single Scene.
+type App struct {
+	*window
+	*vrSystem
+	gl                *opengl.GraphicsImpl
+	height            uint32
+	width             uint32
+	renderWidth       int32
+	renderHeight      int32
+	eyeLeft           *fizzlevr.EyeFramebuffer
+	eyeRight          *fizzlevr.EyeFramebuffer
+	eyeTransforms     *openvr.EyeTransforms
+	distortionLens    *fizzlevr.DistortionLens
+	kbModel           *glfwinput.KeyboardModel
+	vrCompositor      *openvr.Compositor
+	poses             map[uint32]Pose
+	renderer          *forward.ForwardRenderer
+	deviceRenderables *fizzlevr.DeviceRenderables
+
+	ctrlModel     *vrinput.Model
+	lastFrameTime float64
+
+	stats         Stats
+	msaaEnabled   bool
+	renderScale   float32
+	gpuTimerLeft  *gpuTimer
+	gpuTimerRight *gpuTimer
+	hudText       *hudOverlay
+
+	mirrorMode MirrorMode
+	chaperone  *Chaperone
+
+	scene Scene
+}
+
+// NewApp creates the VR harness with the given companion window title,
+// initializes scene against it, and binds controller input if scene wants
+// it.
+func NewApp(title string, scene Scene) (*App, error) {
+	win, err := newWindow(1280, 720, title)
+	if err != nil {
+		return nil, err
+	}
+
+	kbModel := glfwinput.NewKeyboardModel(win.Window)
+	kbModel.BindTrigger(glfw.KeyEscape, func() { win.SetShouldClose(true) })
+
+	vr, err := newVR()
+	if err != nil {
+		return nil, err
+	}
+
+	gl, err := opengl.InitOpenGL()
+	if err != nil {
+		return nil, err
+	}
+	fizzle.SetGraphics(gl)
+
+	sr, sl, err := newShaders()
+	if err != nil {
+		return nil, err
+	}
+
+	// create a new renderer
+	renderer := forward.NewForwardRenderer(gl)
+	width, height := vr.GetRecommendedRenderTargetSize()
+	renderer.ChangeResolution(int32(width), int32(height))
+
+	// put a light in there
+	light := renderer.NewDirectionalLight(mgl32.Vec3{1.0, -0.5, -1.0})
+	light.DiffuseIntensity = 0.70
+	light.SpecularIntensity = 0.10
+	light.AmbientIntensity = 0.3
+	renderer.ActiveLights[0] = light
+
+	if err := scene.Init(renderer); err != nil {
+		return nil, err
+	}
+
+	// FIXME: What exactly do those mean?
+	eyeTransforms := vr.GetEyeTransforms(0.1, 30.0)
+	eyeLeft, eyeRight := fizzlevr.CreateStereoRenderTargets(width, height)
+	distortionLens := fizzlevr.CreateDistortionLens(vr.System, sl, eyeLeft, eyeRight)
+
+	deviceRenderables, err := fizzlevr.CreateDeviceRenderables(vr.System, sr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrlModel := vrinput.NewModel(vr.System)
+	if cs, ok := scene.(ControllerScene); ok {
+		cs.BindControllers(ctrlModel)
+	}
+
+	chaperoneShader, err := forward.CreateBasicShader()
+	if err != nil {
+		return nil, err
+	}
+	chaperone, err := newChaperone(chaperoneShader)
+	if err != nil {
+		fmt.Printf("Chaperone boundary disabled: %v\n", err)
+	} else if cs, ok := scene.(ChaperoneScene); ok {
+		cs.BindChaperone(chaperone)
+	}
+
+	hudText, err := newHUDOverlay()
+	if err != nil {
+		fmt.Printf("HUD overlay disabled: %v\n", err)
+	}
+
+	app := &App{
+		window:            win,
+		vrSystem:          vr,
+		gl:                gl,
+		height:            height,
+		width:             width,
+		renderWidth:       int32(width),
+		renderHeight:      int32(height),
+		eyeLeft:           eyeLeft,
+		eyeRight:          eyeRight,
+		eyeTransforms:     eyeTransforms,
+		distortionLens:    distortionLens,
+		kbModel:           kbModel,
+		vrCompositor:      vr.compositor,
+		deviceRenderables: deviceRenderables,
+		renderer:          renderer,
+		ctrlModel:         ctrlModel,
+		lastFrameTime:     glfw.GetTime(),
+		msaaEnabled:       true,
+		renderScale:       maxRenderScale,
+		gpuTimerLeft:      newGPUTimer(gl),
+		gpuTimerRight:     newGPUTimer(gl),
+		hudText:           hudText,
+		scene:             scene,
+		mirrorMode:        MirrorDistorted,
+		chaperone:         chaperone,
+	}
+	kbModel.BindTrigger(glfw.KeyM, app.cycleMirrorMode)
+	kbModel.SetupCallbacks()
+	return app, nil
+}
+
+// Run drives the scene until the companion window is closed.
+func (a *App) Run() {
+	for !a.window.ShouldClose() {
+		a.handleInput()
+		a.renderFrame()
+	}
+}
+
+func (a *App) handleInput() {
+	// advise GLFW to poll for input. without this the window appears to hang.
+	glfw.PollEvents()
+
+	// handle any keyboard input
+	a.kbModel.CheckKeyPresses()
+
+	var event openvr.VREvent
+	for a.vrSystem.PollNextEvent(&event) {
+		switch event.EventType {
+		case openvr.VREventTrackedDeviceActivated:
+			fmt.Printf("Device %d attached.\n", event.TrackedDeviceIndex)
+		case openvr.VREventTrackedDeviceDeactivated:
+			fmt.Printf("Device %d detached.\n", event.TrackedDeviceIndex)
+		case openvr.VREventTrackedDeviceUpdated:
+			fmt.Printf("Device %d updated.\n", event.TrackedDeviceIndex)
+		}
+	}
+}
+
+func (a *App) renderFrame() {
+	cpuFrameStart := glfw.GetTime()
+
+	now := glfw.GetTime()
+	dt := float32(now - a.lastFrameTime)
+	a.lastFrameTime = now
+	a.scene.Update(dt, a.hmdPose())
+
+	a.renderStereoTargets()
+
+	// draw the framebuffers to the window
+	a.renderCompanionWindow()
+	a.drawHUD()
+
+	// send the framebuffer textures out to the compositor for rendering to the HMD
+	a.vrCompositor.Submit(openvr.EyeLeft, uint32(a.eyeLeft.ResolveTexture))
+	a.vrCompositor.Submit(openvr.EyeRight, uint32(a.eyeRight.ResolveTexture))
+
+	// draw the screen
+	a.window.SwapBuffers()
+	a.stats.CPU = time.Duration((glfw.GetTime() - cpuFrameStart) * float64(time.Second))
+
+	// WaitGetPoses is used as a sync point in the OpenVR API. This is on a timer to keep 90fps, so
+	// the OpenVR gives you that much time to draw a frame. By calling WaitGetPoses() you wait the
+	// remaining amount of time. If you only used 1ms it will wait 10ms here. If you used 5ms it will wait 6ms.
+	// (approx.)
+	waitStart := glfw.GetTime()
+	a.vrCompositor.WaitGetPoses(false)
+	a.stats.PoseWait = time.Duration((glfw.GetTime() - waitStart) * float64(time.Second))
+	a.poses = a.vrSystem.Poses()
+
+	a.ctrlModel.Update(a.vrCompositor)
+	if a.chaperone != nil {
+		a.chaperone.Update(a.hmdPosition())
+	}
+
+	a.stats.GPU = a.gpuTimerLeft.Result(a.gl) + a.gpuTimerRight.Result(a.gl)
+	a.adjustQuality()
+}
+
+func (a *App) renderStereoTargets() {
+	a.gl.Enable(graphics.CULL_FACE)
+	a.gl.ClearColor(0.15, 0.15, 0.18, 1.0) // nice background color, but not black
+
+	// left eye
+	a.gpuTimerLeft.Begin(a.gl)
+	if a.msaaEnabled {
+		a.gl.Enable(graphics.MULTISAMPLE)
+	}
+	a.gl.BindFramebuffer(graphics.FRAMEBUFFER, a.eyeLeft.RenderFramebuffer)
+	a.gl.Viewport(0, 0, a.renderWidth, a.renderHeight)
+	a.renderScene(openvr.EyeLeft)
+	a.gl.BindFramebuffer(graphics.FRAMEBUFFER, 0)
+	a.gl.Disable(graphics.MULTISAMPLE)
+
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, a.eyeLeft.RenderFramebuffer)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, a.eyeLeft.ResolveFramebuffer)
+	a.gl.BlitFramebuffer(0, 0, a.renderWidth, a.renderHeight, 0, 0, int32(a.width), int32(a.height), graphics.COLOR_BUFFER_BIT, graphics.LINEAR)
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, 0)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, 0)
+	a.gpuTimerLeft.End(a.gl)
+
+	// right eye
+	a.gpuTimerRight.Begin(a.gl)
+	if a.msaaEnabled {
+		a.gl.Enable(graphics.MULTISAMPLE)
+	}
+	a.gl.BindFramebuffer(graphics.FRAMEBUFFER, a.eyeRight.RenderFramebuffer)
+	a.gl.Viewport(0, 0, a.renderWidth, a.renderHeight)
+	a.renderScene(openvr.EyeRight)
+	a.gl.BindFramebuffer(graphics.FRAMEBUFFER, 0)
+	a.gl.Disable(graphics.MULTISAMPLE)
+
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, a.eyeRight.RenderFramebuffer)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, a.eyeRight.ResolveFramebuffer)
+	a.gl.BlitFramebuffer(0, 0, a.renderWidth, a.renderHeight, 0, 0, int32(a.width), int32(a.height), graphics.COLOR_BUFFER_BIT, graphics.LINEAR)
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, 0)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, 0)
+	a.gpuTimerRight.End(a.gl)
+}
+
+func (a *App) renderScene(eye int) {
+	a.gl.Clear(graphics.COLOR_BUFFER_BIT | graphics.DEPTH_BUFFER_BIT)
+	a.gl.Enable(graphics.DEPTH_TEST)
+
+	hmdPose := a.hmdPose()
+	hmdPosition := a.hmdPosition()
+
+	var perspective, view mgl32.Mat4
+	var camera FixedCamera
+	if eye == openvr.EyeLeft {
+		view = a.eyeTransforms.PositionLeft.Mul4(hmdPose)
+		perspective = a.eyeTransforms.ProjectionLeft
+		camera.View = view
+		camera.Position = hmdPosition
+	} else {
+		view = a.eyeTransforms.PositionRight.Mul4(hmdPose)
+		perspective = a.eyeTransforms.ProjectionRight
+		camera.View = view
+		camera.Position = hmdPosition
+	}
+
+	for _, obj := range a.scene.Renderables() {
+		a.renderer.DrawRenderable(obj, nil, perspective, view, camera)
+	}
+
+	if a.chaperone != nil {
+		if boundary := a.chaperone.Renderables(); len(boundary) > 0 {
+			a.gl.Enable(graphics.BLEND)
+			a.gl.BlendFunc(graphics.SRC_ALPHA, graphics.ONE_MINUS_SRC_ALPHA)
+			for _, obj := range boundary {
+				a.renderer.DrawRenderable(obj, nil, perspective, view, camera)
+			}
+			a.gl.Disable(graphics.BLEND)
+		}
+	}
+
+	// now draw any devices that get rendered into the scene
+	a.deviceRenderables.RenderDevices(a.vrCompositor, perspective, view, camera)
+}
@@ -0,0 +1,18 @@
+package vrapp
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// FixedCamera implements fizzle's Camera interface with a view matrix and
+// position computed elsewhere (here, from the HMD pose and eye transforms)
+// rather than navigated interactively.
+type FixedCamera struct {
+	View     mgl32.Mat4
+	Position mgl32.Vec3
+}
+
+func (c FixedCamera) GetViewMatrix() mgl32.Mat4 {
+	return c.View
+}
+func (c FixedCamera) GetPosition() mgl32.Vec3 {
+	return c.Position
+}
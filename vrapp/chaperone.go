@@ -0,0 +1,207 @@
+package vrapp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/openvr-go"
+)
+
+const (
+	// boundaryApproachDistance is how close (in meters) the HMD has to get
+	// to the play-area edge before the boundary mesh starts fading in.
+	boundaryApproachDistance = 1.0
+	boundaryHeight           = 2.0
+	boundaryThickness        = 0.02
+)
+
+// BoundaryEvent reports the HMD's distance to the play-area boundary at the
+// moment it crossed an approach or safety threshold.
+type BoundaryEvent struct {
+	Distance float32
+}
+
+// Chaperone tracks the play area reported by OpenVR's chaperone interface
+// and the HMD's position relative to it, emitting events as the HMD nears
+// or crosses the boundary.
+type Chaperone struct {
+	quad     [4]mgl32.Vec3
+	boundary []*fizzle.Renderable
+	material *fizzle.Material
+
+	approaching chan BoundaryEvent
+	crossed     chan BoundaryEvent
+
+	visible        bool
+	wasApproaching bool
+	wasCrossed     bool
+}
+
+// newChaperone fetches the play area quad from OpenVR and builds the
+// translucent boundary mesh for it. It returns an error if no play area is
+// configured (e.g. stationary-only setups).
+func newChaperone(sb *fizzle.RenderShader) (*Chaperone, error) {
+	chap, err := openvr.GetChaperone()
+	if err != nil {
+		return nil, err
+	}
+	rect, ok := chap.GetPlayAreaRect()
+	if !ok {
+		return nil, fmt.Errorf("chaperone: no play area configured")
+	}
+
+	var quad [4]mgl32.Vec3
+	for i, c := range rect.Corners {
+		quad[i] = mgl32.Vec3{c.V[0], c.V[1], c.V[2]}
+	}
+
+	material := fizzle.NewMaterial()
+	material.Shader = sb
+	material.DiffuseColor = mgl32.Vec4{1.0, 0.3, 0.3, 0.0}
+
+	var boundary []*fizzle.Renderable
+	for i := range quad {
+		boundary = append(boundary, wallSegment(quad[i], quad[(i+1)%len(quad)], material))
+	}
+
+	return &Chaperone{
+		quad:        quad,
+		boundary:    boundary,
+		material:    material,
+		approaching: make(chan BoundaryEvent, 1),
+		crossed:     make(chan BoundaryEvent, 1),
+	}, nil
+}
+
+// wallSegment builds a thin wall box spanning from a to b, rotated to run
+// along the edge itself rather than assuming it's X- or Z-aligned: a real
+// play area can be rotated arbitrarily in the tracking frame.
+func wallSegment(a, b mgl32.Vec3, material *fizzle.Material) *fizzle.Renderable {
+	mid := a.Add(b).Mul(0.5)
+	delta := b.Sub(a)
+
+	halfThick, halfHigh, halfLen := boundaryThickness/2, boundaryHeight/2, delta.Len()/2
+	r := fizzle.CreateCube(-halfThick, -halfHigh, -halfLen, halfThick, halfHigh, halfLen)
+	r.Material = material
+	r.Location = mid
+	heading := float32(math.Atan2(float64(delta[0]), float64(delta[2])))
+	r.LocalRotation = mgl32.QuatRotate(heading, mgl32.Vec3{0, 1, 0})
+	return r
+}
+
+// Approaching receives an event whenever the HMD comes within
+// boundaryApproachDistance of the play-area edge.
+func (c *Chaperone) Approaching() <-chan BoundaryEvent {
+	return c.approaching
+}
+
+// Crossed receives an event whenever the HMD exits the play area.
+func (c *Chaperone) Crossed() <-chan BoundaryEvent {
+	return c.crossed
+}
+
+// Renderables returns the translucent boundary mesh to draw this frame, or
+// nil while the HMD is well clear of the play-area edge. Draw whatever this
+// returns with blending enabled: the mesh's alpha is what actually fades it
+// in as the HMD approaches.
+func (c *Chaperone) Renderables() []*fizzle.Renderable {
+	if !c.visible {
+		return nil
+	}
+	return c.boundary
+}
+
+// Update fades the boundary mesh in as hmdPos approaches the play-area edge
+// and emits Approaching/Crossed events on state transitions.
+func (c *Chaperone) Update(hmdPos mgl32.Vec3) {
+	dist, inside := distanceToQuad(hmdPos, c.quad)
+
+	fade := float32(0)
+	if inside && dist < boundaryApproachDistance {
+		fade = 1 - dist/boundaryApproachDistance
+	} else if !inside {
+		fade = 1
+	}
+	c.material.DiffuseColor[3] = fade
+
+	approaching := inside && dist < boundaryApproachDistance
+	if approaching && !c.wasApproaching {
+		c.send(c.approaching, BoundaryEvent{Distance: dist})
+	}
+	c.wasApproaching = approaching
+
+	crossed := !inside
+	if crossed && !c.wasCrossed {
+		c.send(c.crossed, BoundaryEvent{Distance: dist})
+	}
+	c.wasCrossed = crossed
+
+	c.visible = approaching || crossed
+}
+
+func (c *Chaperone) send(ch chan BoundaryEvent, evt BoundaryEvent) {
+	select {
+	case ch <- evt:
+	default:
+	}
+}
+
+// distanceToQuad returns the HMD's distance to the nearest of quad's true
+// edges in the XZ plane, and whether it's still inside the quad. quad need
+// not be axis-aligned: a real SteamVR play area can be rotated arbitrarily
+// in the tracking frame.
+func distanceToQuad(pos mgl32.Vec3, quad [4]mgl32.Vec3) (dist float32, inside bool) {
+	p := mgl32.Vec2{pos[0], pos[2]}
+
+	dist = float32(math.MaxFloat32)
+	sign := 0
+	for i := range quad {
+		a := mgl32.Vec2{quad[i][0], quad[i][2]}
+		b := mgl32.Vec2{quad[(i+1)%len(quad)][0], quad[(i+1)%len(quad)][2]}
+
+		cross := (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+		switch {
+		case cross > 0:
+			sign++
+		case cross < 0:
+			sign--
+		}
+
+		dist = minF32(dist, distanceToSegment(p, a, b))
+	}
+
+	inside = sign == 4 || sign == -4
+	if !inside {
+		return 0, false
+	}
+	return dist, true
+}
+
+// distanceToSegment returns p's distance to the line segment a-b.
+func distanceToSegment(p, a, b mgl32.Vec2) float32 {
+	edge := b.Sub(a)
+	lenSq := edge.Dot(edge)
+	if lenSq == 0 {
+		return p.Sub(a).Len()
+	}
+	t := p.Sub(a).Dot(edge) / lenSq
+	t = maxF32(0, minF32(1, t))
+	closest := a.Add(edge.Mul(t))
+	return p.Sub(closest).Len()
+}
+
+func minF32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxF32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
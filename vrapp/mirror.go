@@ -0,0 +1,86 @@
+package vrapp
+
+import (
+	"fmt"
+
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	"github.com/tbogdala/openvr-go/util/fizzlevr"
+)
+
+// MirrorMode controls what, if anything, the companion window shows while
+// the headset is being driven.
+type MirrorMode int
+
+const (
+	// MirrorDistorted shows both eyes through the lens distortion, as seen
+	// in the headset. This is the default.
+	MirrorDistorted MirrorMode = iota
+	// MirrorLeftEye shows the left eye's render target, undistorted.
+	MirrorLeftEye
+	// MirrorRightEye shows the right eye's render target, undistorted.
+	MirrorRightEye
+	// MirrorBoth shows both eyes side-by-side, undistorted.
+	MirrorBoth
+	// MirrorDisabled skips the companion-window blit entirely, saving GPU
+	// time when nobody's watching the desktop.
+	MirrorDisabled
+)
+
+func (m MirrorMode) String() string {
+	switch m {
+	case MirrorDistorted:
+		return "distorted"
+	case MirrorLeftEye:
+		return "left-eye"
+	case MirrorRightEye:
+		return "right-eye"
+	case MirrorBoth:
+		return "both"
+	case MirrorDisabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("MirrorMode(%d)", int(m))
+	}
+}
+
+// MirrorMode returns the companion window's current mirror mode.
+func (a *App) MirrorMode() MirrorMode {
+	return a.mirrorMode
+}
+
+// SetMirrorMode changes what the companion window shows.
+func (a *App) SetMirrorMode(mode MirrorMode) {
+	a.mirrorMode = mode
+}
+
+func (a *App) cycleMirrorMode() {
+	a.mirrorMode = (a.mirrorMode + 1) % (MirrorDisabled + 1)
+	fmt.Printf("Companion window mirror mode: %s\n", a.mirrorMode)
+}
+
+func (a *App) renderCompanionWindow() {
+	switch a.mirrorMode {
+	case MirrorDisabled:
+		return
+	case MirrorDistorted:
+		a.distortionLens.Render(int32(a.width), int32(a.height))
+	case MirrorLeftEye:
+		a.blitEyeToWindow(a.eyeLeft, 0, 0, a.window.width, a.window.height)
+	case MirrorRightEye:
+		a.blitEyeToWindow(a.eyeRight, 0, 0, a.window.width, a.window.height)
+	case MirrorBoth:
+		half := a.window.width / 2
+		a.blitEyeToWindow(a.eyeLeft, 0, 0, half, a.window.height)
+		a.blitEyeToWindow(a.eyeRight, half, 0, a.window.width-half, a.window.height)
+	}
+}
+
+// blitEyeToWindow blits eye's resolved (undistorted) render target into the
+// companion window's default framebuffer at the given rectangle.
+func (a *App) blitEyeToWindow(eye *fizzlevr.EyeFramebuffer, x, y, w, h int) {
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, eye.ResolveFramebuffer)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, 0)
+	a.gl.BlitFramebuffer(0, 0, int32(a.width), int32(a.height), int32(x), int32(y), int32(x+w), int32(y+h), graphics.COLOR_BUFFER_BIT, graphics.LINEAR)
+	a.gl.BindFramebuffer(graphics.READ_FRAMEBUFFER, 0)
+	a.gl.BindFramebuffer(graphics.DRAW_FRAMEBUFFER, 0)
+}
@@ -0,0 +1,35 @@
+package vrapp
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/openvr-go"
+)
+
+// Pose is a tracked device's full 6-DoF state for a single frame.
+type Pose struct {
+	Position        mgl32.Vec3
+	Orientation     mgl32.Quat
+	LinearVelocity  mgl32.Vec3
+	AngularVelocity mgl32.Vec3
+}
+
+// Mat4 reconstructs the device's world transform from Position and
+// Orientation.
+func (p Pose) Mat4() mgl32.Mat4 {
+	return mgl32.Translate3D(p.Position[0], p.Position[1], p.Position[2]).Mul4(p.Orientation.Mat4())
+}
+
+// hmdPose returns the inverse of the HMD's world transform, suitable for use
+// as the base view matrix for stereo rendering.
+func (a *App) hmdPose() mgl32.Mat4 {
+	pose, ok := a.poses[openvr.TrackedDeviceIndexHmd]
+	if !ok {
+		return mgl32.Ident4()
+	}
+	return pose.Mat4().Inv()
+}
+
+// hmdPosition returns the HMD's last tracked position.
+func (a *App) hmdPosition() mgl32.Vec3 {
+	return a.poses[openvr.TrackedDeviceIndexHmd].Position
+}
@@ -0,0 +1,21 @@
+package vrapp
+
+import (
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/openvr-go"
+)
+
+// newShaders compiles the shaders the harness itself needs to draw tracked
+// device models and the lens-distorted companion view. Scenes are
+// responsible for their own shaders (e.g. via forward.CreateBasicShader).
+func newShaders() (renderModel, lensDistortion *fizzle.RenderShader, err error) {
+	renderModel, err = fizzle.LoadShaderProgram(openvr.ShaderRenderModelV, openvr.ShaderRenderModelF, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	lensDistortion, err = fizzle.LoadShaderProgram(openvr.ShaderLensDistortionV, openvr.ShaderLensDistortionF, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return renderModel, lensDistortion, nil
+}
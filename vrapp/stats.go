@@ -0,0 +1,167 @@
+package vrapp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	"github.com/tbogdala/fizzle/graphicsprovider/opengl"
+)
+
+// frameBudget is the time a frame has to render in to keep up with a 90 Hz
+// headset; exceeding it for too long triggers adaptive quality drops.
+const frameBudget = time.Second / 90
+
+const (
+	minRenderScale  = 0.7
+	maxRenderScale  = 1.0
+	renderScaleStep = 0.05
+)
+
+// Stats reports per-frame timing and the adaptive-quality settings the
+// renderer is currently using.
+type Stats struct {
+	CPU         time.Duration
+	GPU         time.Duration
+	PoseWait    time.Duration
+	MSAAEnabled bool
+	RenderScale float32
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("cpu %s  gpu %s  wait %s  msaa %v  scale %.2f",
+		s.CPU.Round(time.Microsecond), s.GPU.Round(time.Microsecond), s.PoseWait.Round(time.Microsecond), s.MSAAEnabled, s.RenderScale)
+}
+
+// gpuTimer measures elapsed GPU time for a span of draw calls using a
+// GL_TIME_ELAPSED query. It ping-pongs between two query objects so Result
+// always reads the query that finished a full frame ago rather than the one
+// just begun, which would otherwise stall the CPU waiting on the GPU.
+type gpuTimer struct {
+	queries [2]uint32
+	cur     int
+	ready   [2]bool
+}
+
+func newGPUTimer(gl *opengl.GraphicsImpl) *gpuTimer {
+	t := &gpuTimer{}
+	gl.GenQueries(2, &t.queries[0])
+	return t
+}
+
+func (t *gpuTimer) Begin(gl *opengl.GraphicsImpl) {
+	gl.BeginQuery(graphics.TIME_ELAPSED, t.queries[t.cur])
+}
+
+func (t *gpuTimer) End(gl *opengl.GraphicsImpl) {
+	gl.EndQuery(graphics.TIME_ELAPSED)
+	t.ready[t.cur] = true
+	t.cur = 1 - t.cur
+}
+
+// Result returns the GPU time for the query that completed last frame,
+// without blocking: if the driver hasn't made the result available yet it
+// returns 0 rather than stalling on GL_QUERY_RESULT.
+func (t *gpuTimer) Result(gl *opengl.GraphicsImpl) time.Duration {
+	idx := t.cur
+	if !t.ready[idx] {
+		return 0
+	}
+	var available uint32
+	gl.GetQueryObjectuiv(t.queries[idx], graphics.QUERY_RESULT_AVAILABLE, &available)
+	if available == 0 {
+		return 0
+	}
+	var elapsedNs uint64
+	gl.GetQueryObjectui64v(t.queries[idx], graphics.QUERY_RESULT, &elapsedNs)
+	return time.Duration(elapsedNs)
+}
+
+// Stats returns the most recently measured frame timing and adaptive
+// quality settings.
+func (a *App) Stats() Stats {
+	return a.stats
+}
+
+// adjustQuality trades render-target resolution (and, once at the floor,
+// MSAA) against frame budget: dropping quality when a frame runs over
+// frameBudget, and restoring it once there's headroom to spare again.
+func (a *App) adjustQuality() {
+	total := a.stats.CPU + a.stats.GPU
+	switch {
+	case total > frameBudget && a.msaaEnabled:
+		a.msaaEnabled = false
+	case total > frameBudget && a.renderScale > minRenderScale:
+		a.renderScale -= renderScaleStep
+		a.applyRenderScale()
+	case total < frameBudget/2 && a.renderScale < maxRenderScale:
+		a.renderScale += renderScaleStep
+		a.applyRenderScale()
+	case total < frameBudget/2 && !a.msaaEnabled:
+		a.msaaEnabled = true
+	}
+	a.stats.MSAAEnabled = a.msaaEnabled
+	a.stats.RenderScale = a.renderScale
+}
+
+// applyRenderScale shrinks the viewport each eye is actually rendered into
+// (renderStereoTargets blits it back up to the full eye texture), so
+// renderScale below 1.0 cuts the pixels shaded per frame rather than just
+// resizing a renderer target nothing reads from.
+func (a *App) applyRenderScale() {
+	a.renderWidth = int32(float32(a.width) * a.renderScale)
+	a.renderHeight = int32(float32(a.height) * a.renderScale)
+	a.renderer.ChangeResolution(a.renderWidth, a.renderHeight)
+}
+
+// drawHUD renders the frame-timing overlay to the companion window using the
+// already-bound default framebuffer, so it must run after the eye
+// framebuffers are blitted out and before SwapBuffers.
+func (a *App) drawHUD() {
+	if a.hudText == nil {
+		return
+	}
+	a.hudText.SetText(a.stats.String())
+	ortho := mgl32.Ortho2D(0, float32(a.window.width), float32(a.window.height), 0)
+	a.gl.Viewport(0, 0, int32(a.window.width), int32(a.window.height))
+	a.hudText.Render(ortho)
+}
+
+// hudOverlay draws debug text over the companion window using fizzle's
+// bitmap font support.
+type hudOverlay struct {
+	font     *fizzle.BitmapFont
+	text     *fizzle.Renderable
+	lastText string
+}
+
+func newHUDOverlay() (*hudOverlay, error) {
+	font, err := fizzle.LoadBitmapFont("assets/fonts/debug.fnt", "assets/fonts/debug.png")
+	if err != nil {
+		return nil, err
+	}
+	return &hudOverlay{font: font}, nil
+}
+
+// SetText rebuilds the HUD renderable only when the text actually changed,
+// destroying the previous one first so its GL buffers don't leak: this runs
+// every frame, and CreateText allocates a new Renderable each call.
+func (h *hudOverlay) SetText(s string) {
+	if s == h.lastText {
+		return
+	}
+	if h.text != nil {
+		h.text.Destroy()
+	}
+	h.text = h.font.CreateText(mgl32.Vec3{10, 10, 0}, mgl32.Vec4{1, 1, 1, 1}, s)
+	h.lastText = s
+}
+
+func (h *hudOverlay) Render(ortho mgl32.Mat4) {
+	if h.text == nil {
+		return
+	}
+	h.font.Render(h.text, ortho)
+}
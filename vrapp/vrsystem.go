@@ -0,0 +1,80 @@
+package vrapp
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/openvr-go"
+)
+
+type vrSystem struct {
+	*openvr.System
+	compositor *openvr.Compositor
+}
+
+// vrSystem
+func newVR() (*vrSystem, error) {
+	vrs, err := openvr.Init()
+	if err != nil {
+		return nil, err
+	}
+	// FIXME: Example checks this. Necessary?
+	if vrs == nil {
+		panic("BUG")
+	}
+	vr := &vrSystem{System: vrs}
+
+	name, err := vr.deviceProperty(openvr.TrackedDeviceIndexHmd, openvr.PropTrackingSystemNameString)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := vr.deviceProperty(openvr.TrackedDeviceIndexHmd, openvr.PropSerialNumberString)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Connected to %s %s\n", name, dsn)
+
+	vr.compositor, err = openvr.GetCompositor()
+	if err != nil {
+		return nil, err
+	}
+	return vr, nil
+}
+
+// Poses returns the full 6-DoF pose for every currently tracked device
+// (HMD, controllers and trackers) as of the last WaitGetPoses call, keyed
+// by OpenVR device index.
+func (vr *vrSystem) Poses() map[uint32]Pose {
+	poses := make(map[uint32]Pose)
+	for i := uint32(0); i < openvr.MaxTrackedDeviceCount; i++ {
+		if !vr.compositor.IsPoseValid(i) {
+			continue
+		}
+		poses[i] = poseFromTrackedDevicePose(vr.compositor.GetRenderPose(i))
+	}
+	return poses
+}
+
+// poseFromTrackedDevicePose converts OpenVR's row-major 3x4
+// DeviceToAbsoluteTracking matrix into a Pose. Translation lives at indices
+// 3, 7 and 11 of that matrix (the last column of each row), not 9-11.
+func poseFromTrackedDevicePose(raw openvr.TrackedDevicePose) Pose {
+	m := raw.DeviceToAbsoluteTracking
+	position := mgl32.Vec3{m[3], m[7], m[11]}
+	orientation := mgl32.Mat4ToQuat(mgl32.Mat4(openvr.Mat34ToMat4(&m)))
+	return Pose{
+		Position:        position,
+		Orientation:     orientation,
+		LinearVelocity:  mgl32.Vec3{raw.Velocity[0], raw.Velocity[1], raw.Velocity[2]},
+		AngularVelocity: mgl32.Vec3{raw.AngularVelocity[0], raw.AngularVelocity[1], raw.AngularVelocity[2]},
+	}
+}
+
+func (vr *vrSystem) deviceProperty(device, property uint) (string, error) {
+	val, status := vr.GetStringTrackedDeviceProperty(int(device), int(property))
+	if status != openvr.TrackedPropSuccess {
+		return "", fmt.Errorf("Couldn't get property %d from device %d", property, device)
+	}
+	return val, nil
+}
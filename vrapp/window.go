@@ -0,0 +1,45 @@
+package vrapp
+
+import "github.com/go-gl/glfw/v3.1/glfw"
+
+// window wraps a GLFW window and tracks its current size, since GLFW only
+// reports that asynchronously via a callback.
+type window struct {
+	*glfw.Window
+	width  int
+	height int
+	title  string
+}
+
+func newWindow(width, height int, title string) (*window, error) {
+	if err := glfw.Init(); err != nil {
+		return nil, err
+	}
+	for hint, value := range map[glfw.Hint]int{
+		glfw.Samples:                 4,
+		glfw.ContextVersionMajor:     3,
+		glfw.ContextVersionMinor:     3,
+		glfw.OpenGLForwardCompatible: glfw.True,
+		glfw.OpenGLProfile:           glfw.OpenGLCoreProfile,
+	} {
+		glfw.WindowHint(hint, value)
+	}
+	glwin, err := glfw.CreateWindow(width, height, title, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	win := &window{
+		Window: glwin,
+		width:  width,
+		height: height,
+		title:  title,
+	}
+	glwin.SetSizeCallback(func(w *glfw.Window, width int, height int) {
+		win.width = width
+		win.height = height
+	})
+	win.MakeContextCurrent()
+	glfw.SwapInterval(0) // Disable v-sync
+	return win, nil
+}
@@ -0,0 +1,191 @@
+// Package vrinput provides a Go-idiomatic event/callback API for OpenVR
+// controller input, layered on top of openvr.System.GetControllerState in
+// the same spirit as glfwinput.KeyboardModel's Bind*/Check* pattern.
+package vrinput
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/openvr-go"
+)
+
+// Hand identifies which controller a binding or pose applies to.
+type Hand int
+
+const (
+	HandLeft Hand = iota
+	HandRight
+)
+
+// role returns the ETrackedControllerRole value (PropControllerRoleHintInt32)
+// openvr reports for hand's controller.
+func (h Hand) role() int {
+	if h == HandLeft {
+		return openvr.TrackedControllerRoleLeftHand
+	}
+	return openvr.TrackedControllerRoleRightHand
+}
+
+// padAxisIndex and triggerAxisIndex are ControllerState.Axis's slots for the
+// Vive wand controllers this package targets: axis 0 is the trackpad, axis 1
+// the analog trigger.
+const (
+	padAxisIndex     = 0
+	triggerAxisIndex = 1
+)
+
+// Model tracks OpenVR controller button, axis and pose state and dispatches
+// bound callbacks for button transitions.
+type Model struct {
+	vr *openvr.System
+
+	triggerPress   map[Hand]func(pose mgl32.Mat4)
+	triggerRelease map[Hand]func(pose mgl32.Mat4)
+	padPress       map[Hand]func(pose mgl32.Mat4)
+	padRelease     map[Hand]func(pose mgl32.Mat4)
+
+	poses       map[Hand]mgl32.Mat4
+	lastButtons map[Hand]uint64
+}
+
+// NewModel creates a controller input model bound to vr.
+func NewModel(vr *openvr.System) *Model {
+	return &Model{
+		vr:             vr,
+		triggerPress:   make(map[Hand]func(pose mgl32.Mat4)),
+		triggerRelease: make(map[Hand]func(pose mgl32.Mat4)),
+		padPress:       make(map[Hand]func(pose mgl32.Mat4)),
+		padRelease:     make(map[Hand]func(pose mgl32.Mat4)),
+		poses:          make(map[Hand]mgl32.Mat4),
+		lastButtons:    make(map[Hand]uint64),
+	}
+}
+
+// OnTriggerPress registers fn to be called with hand's current pose whenever
+// its trigger transitions from released to pressed.
+func (m *Model) OnTriggerPress(hand Hand, fn func(pose mgl32.Mat4)) {
+	m.triggerPress[hand] = fn
+}
+
+// OnTriggerRelease registers fn to be called with hand's current pose
+// whenever its trigger is released.
+func (m *Model) OnTriggerRelease(hand Hand, fn func(pose mgl32.Mat4)) {
+	m.triggerRelease[hand] = fn
+}
+
+// OnPadPress registers fn to be called when hand's trackpad is pressed.
+func (m *Model) OnPadPress(hand Hand, fn func(pose mgl32.Mat4)) {
+	m.padPress[hand] = fn
+}
+
+// OnPadRelease registers fn to be called when hand's trackpad is released.
+func (m *Model) OnPadRelease(hand Hand, fn func(pose mgl32.Mat4)) {
+	m.padRelease[hand] = fn
+}
+
+// Pose returns the most recently polled pose for hand's controller.
+func (m *Model) Pose(hand Hand) mgl32.Mat4 {
+	return m.poses[hand]
+}
+
+// TriggerAxis returns hand's analog trigger pull, from 0 (released) to 1
+// (fully pressed).
+func (m *Model) TriggerAxis(hand Hand) float32 {
+	state, ok := m.controllerState(hand)
+	if !ok {
+		return 0
+	}
+	return state.Axis[triggerAxisIndex].X
+}
+
+// PadAxis returns hand's trackpad position, with X and Y each in [-1, 1].
+func (m *Model) PadAxis(hand Hand) mgl32.Vec2 {
+	state, ok := m.controllerState(hand)
+	if !ok {
+		return mgl32.Vec2{}
+	}
+	axis := state.Axis[padAxisIndex]
+	return mgl32.Vec2{axis.X, axis.Y}
+}
+
+func (m *Model) controllerState(hand Hand) (openvr.ControllerState, bool) {
+	index, ok := m.deviceIndex(hand)
+	if !ok {
+		return openvr.ControllerState{}, false
+	}
+	var state openvr.ControllerState
+	if !m.vr.GetControllerState(int(index), &state) {
+		return openvr.ControllerState{}, false
+	}
+	return state, true
+}
+
+// deviceIndex finds the tracked device index currently holding hand's
+// controller role. openvr-go doesn't bind SteamVR's
+// GetTrackedDeviceIndexForControllerRole, so this scans the connected
+// controllers and reads each one's role hint property directly.
+func (m *Model) deviceIndex(hand Hand) (uint, bool) {
+	role := hand.role()
+	for i := uint(0); i < openvr.MaxTrackedDeviceCount; i++ {
+		if !m.vr.IsTrackedDeviceConnected(uint32(i)) {
+			continue
+		}
+		if m.vr.GetTrackedDeviceClass(int(i)) != openvr.TrackedDeviceClassController {
+			continue
+		}
+		hint, status := m.vr.GetInt32TrackedDeviceProperty(int(i), openvr.PropControllerRoleHintInt32)
+		if status == openvr.TrackedPropSuccess && int(hint) == role {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Update refreshes each hand's pose from the compositor's render poses for
+// this frame and dispatches press/release callbacks for button edge
+// transitions. Call this once per frame, alongside the HMD pose update.
+//
+// Edges are detected here by diffing ControllerState.ButtonPressed against
+// last frame's value, rather than from PollNextEvent: openvr-go's VREvent
+// doesn't expose which button a button event was for.
+func (m *Model) Update(compositor *openvr.Compositor) {
+	for _, hand := range []Hand{HandLeft, HandRight} {
+		index, ok := m.deviceIndex(hand)
+		if !ok {
+			continue
+		}
+
+		if compositor.IsPoseValid(index) {
+			pose := compositor.GetRenderPose(index)
+			m.poses[hand] = mgl32.Mat4(openvr.Mat34ToMat4(&pose.DeviceToAbsoluteTracking))
+		}
+
+		var state openvr.ControllerState
+		if !m.vr.GetControllerState(int(index), &state) {
+			continue
+		}
+		m.dispatchEdges(hand, state.ButtonPressed)
+	}
+}
+
+func (m *Model) dispatchEdges(hand Hand, buttons uint64) {
+	pose := m.poses[hand]
+	m.dispatchEdge(hand, pose, buttons, openvr.ButtonSteamVRTrigger, m.triggerPress, m.triggerRelease)
+	m.dispatchEdge(hand, pose, buttons, openvr.ButtonSteamVRTouchpad, m.padPress, m.padRelease)
+	m.lastButtons[hand] = buttons
+}
+
+func (m *Model) dispatchEdge(hand Hand, pose mgl32.Mat4, buttons uint64, button uint, press, release map[Hand]func(mgl32.Mat4)) {
+	bit := uint64(1) << button
+	now := buttons&bit != 0
+	was := m.lastButtons[hand]&bit != 0
+	switch {
+	case now && !was:
+		if fn := press[hand]; fn != nil {
+			fn(pose)
+		}
+	case !now && was:
+		if fn := release[hand]; fn != nil {
+			fn(pose)
+		}
+	}
+}